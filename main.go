@@ -17,6 +17,7 @@ import (
 	"math"
 	"math/rand"
 	"net/http"
+	"net/url"
 	"os"
 	"path"
 	"path/filepath"
@@ -52,6 +53,7 @@ type Config struct {
 	MaxBytesPerFile      int64
 	MaxBytesPerFileHuman string
 	MinutesPerGigabyte   float64
+	Check                bool
 }
 
 // uploads keep track of parallel chunking
@@ -73,6 +75,7 @@ func main() {
 	flag.Int64Var(&c.MaxBytesPerFile, "max-file", 100000000, "max bytes per file")
 	flag.Int64Var(&c.MaxBytesTotal, "max-total", 10000000000, "max bytes total")
 	flag.Float64Var(&c.MinutesPerGigabyte, "min-per-gig", 30, "number of minutes per gigabyte to scale auto-deletion")
+	flag.BoolVar(&c.Check, "check", false, "validate the configuration and exit")
 	flag.Parse()
 
 	// set a random seed for random activities
@@ -106,6 +109,15 @@ func main() {
 	if c.PublicURL == "" {
 		c.PublicURL = "http://localhost:" + c.Port
 	}
+	if c.Check {
+		err = checkConfig()
+		if err != nil {
+			log.Errorf("configuration is invalid: %s", err.Error())
+			os.Exit(1)
+		}
+		log.Info("configuration is valid")
+		return
+	}
 	os.Mkdir(c.ContentDirectory, os.ModePerm)
 
 	// go routine for deleting old files
@@ -124,6 +136,61 @@ func main() {
 	http.ListenAndServe(":"+c.Port, nil)
 }
 
+// checkConfig validates the flags and makes sure the content directory
+// can be written to, so that deployments can catch problems before starting.
+func checkConfig() (err error) {
+	port, err := strconv.Atoi(c.Port)
+	if err != nil || port < 1 || port > 65535 {
+		return fmt.Errorf("invalid port '%s'", c.Port)
+	}
+	u, err := url.Parse(c.PublicURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("invalid public URL '%s'", c.PublicURL)
+	}
+	if c.MaxBytesPerFile <= 0 || c.MaxBytesTotal <= 0 {
+		return fmt.Errorf("max bytes must be positive")
+	}
+	if c.MaxBytesPerFile > c.MaxBytesTotal {
+		return fmt.Errorf("max bytes per file (%d) exceeds max bytes total (%d)", c.MaxBytesPerFile, c.MaxBytesTotal)
+	}
+	if c.MinutesPerGigabyte <= 0 {
+		return fmt.Errorf("minutes per gigabyte must be positive")
+	}
+
+	// make sure the content directory is writable, or that it can be
+	// created the same way startup does (non-recursively)
+	info, err := os.Stat(c.ContentDirectory)
+	if err == nil {
+		if !info.IsDir() {
+			return fmt.Errorf("content directory '%s' is not a directory", c.ContentDirectory)
+		}
+		err = checkWritable(c.ContentDirectory)
+		if err != nil {
+			return fmt.Errorf("content directory '%s' is not writable: %s", c.ContentDirectory, err.Error())
+		}
+		return
+	}
+	if !os.IsNotExist(err) {
+		return fmt.Errorf("content directory '%s' cannot be read: %s", c.ContentDirectory, err.Error())
+	}
+	err = checkWritable(filepath.Dir(filepath.Clean(c.ContentDirectory)))
+	if err != nil {
+		return fmt.Errorf("content directory '%s' cannot be created: %s", c.ContentDirectory, err.Error())
+	}
+	return
+}
+
+// checkWritable writes and removes a temp file in the directory to make
+// sure that files can be written there.
+func checkWritable(dir string) (err error) {
+	f, err := ioutil.TempFile(dir, "sharetemp")
+	if err != nil {
+		return
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
+
 // deleteOld goes through the files and deletes old uploads
 func deleteOld(removeTempFiles ...bool) {
 	dirSize, _, err := DirSize(c.ContentDirectory)
@@ -484,6 +551,15 @@ func handle(w http.ResponseWriter, r *http.Request) (err error) {
 			jsonResponse(w, http.StatusOK, map[string]string{"exists": "yes", "id": id, "name": name})
 		}
 		return nil
+	} else if r.Method == "GET" && r.URL.Path == "/api/config" {
+		// GET /api/config returns the limits of this server
+		jsonResponse(w, http.StatusOK, map[string]interface{}{
+			"max_bytes_per_file":       c.MaxBytesPerFile,
+			"max_bytes_per_file_human": c.MaxBytesPerFileHuman,
+			"max_bytes_total":          c.MaxBytesTotal,
+			"minutes_per_gigabyte":     c.MinutesPerGigabyte,
+		})
+		return nil
 	} else if r.Method == "GET" && strings.HasPrefix(r.URL.Path, "/static/") {
 		// GET /static/<file> will return the <file> if it exists
 		p.NameOnDisk = strings.TrimPrefix(filepath.ToSlash(filepath.Clean(r.URL.Path[1:])), "/") + ".gz"
@@ -608,7 +684,7 @@ func loadPageInfo(id string) (p *Page, err error) {
 	}
 
 	p.NameOnDisk = path.Join(c.ContentDirectory, p.ID, p.Name)
-	p.TimeToDeletion = (time.Duration(c.MinutesPerGigabyte) * time.Minute) * time.Duration(1000000000/p.Size)
+	p.TimeToDeletion = time.Duration(c.MinutesPerGigabyte*float64(time.Minute)) * time.Duration(1000000000/p.Size)
 	p.TimeToDeletionHuman = durafmt.Parse(p.TimeToDeletion).String()
 	p.ModifiedHuman = HumanizeTime(p.Modified)
 	return
@@ -2,19 +2,148 @@ package main
 
 import (
 	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
 
-func TestWordHash(t *testing.T) {
-	assert.Equal(t, "intensional-catfish", WordHash("test"))
+func TestRandomName(t *testing.T) {
+	assert.Equal(t, "498", RandomName("test"))
+	assert.Equal(t, RandomName("test"), RandomName("test"))
 }
 
 func TestAsset(t *testing.T) {
-	b, err := Asset("static/style.css.gz")
+	b, err := content.ReadFile("static/style.css.gz")
 	assert.Nil(t, err)
-	contentType, err := GetFileContentTypeReader("statc/style.css.gz", bytes.NewBuffer(b))
+	contentType, _, err := GetFileContentTypeReader("statc/style.css.gz", bytes.NewBuffer(b))
 	assert.Nil(t, err)
 	assert.Equal(t, "text/css", contentType)
 }
+
+// writePageInfo writes the meta information for an upload the same way
+// copyToContentDirectory does.
+func writePageInfo(t *testing.T, p *Page) {
+	assert.Nil(t, os.MkdirAll(path.Join(c.ContentDirectory, p.ID), os.ModePerm))
+	f, err := os.Create(path.Join(c.ContentDirectory, p.ID, p.ID+".json.gz"))
+	assert.Nil(t, err)
+	defer f.Close()
+	w := gzip.NewWriter(f)
+	defer w.Close()
+	assert.Nil(t, json.NewEncoder(w).Encode(p))
+}
+
+func TestLoadPageInfoTimeToDeletion(t *testing.T) {
+	oldConfig := c
+	defer func() { c = oldConfig }()
+	c.ContentDirectory = t.TempDir()
+
+	tests := []struct {
+		minutesPerGigabyte float64
+		size               int64
+		expected           time.Duration
+	}{
+		{30, 1000000000, 30 * time.Minute},
+		{30, 100000000, 300 * time.Minute},
+		{0.5, 1000000000, 30 * time.Second},
+		{0.5, 100000000, 5 * time.Minute},
+		{1.5, 1000000000, 90 * time.Second},
+	}
+	for _, tt := range tests {
+		c.MinutesPerGigabyte = tt.minutesPerGigabyte
+		writePageInfo(t, &Page{ID: "123", Name: "test.txt", Size: tt.size})
+		p, err := loadPageInfo("123")
+		assert.Nil(t, err)
+		assert.Equal(t, tt.expected, p.TimeToDeletion, "%v min/GB, %d bytes", tt.minutesPerGigabyte, tt.size)
+	}
+}
+
+func TestCheckConfig(t *testing.T) {
+	oldConfig := c
+	defer func() { c = oldConfig }()
+
+	tests := []struct {
+		name   string
+		setup  func(dir string)
+		err    string
+		exists bool
+	}{
+		{"valid", func(dir string) {}, "", true},
+		{"missing data directory", func(dir string) {
+			c.ContentDirectory = filepath.Join(dir, "data")
+		}, "", false},
+		{"port not a number", func(dir string) { c.Port = "x" }, "invalid port 'x'", true},
+		{"port out of range", func(dir string) { c.Port = "70000" }, "invalid port '70000'", true},
+		{"public URL without scheme", func(dir string) { c.PublicURL = "localhost" }, "invalid public URL 'localhost'", true},
+		{"max file not positive", func(dir string) { c.MaxBytesPerFile = 0 }, "max bytes must be positive", true},
+		{"max total not positive", func(dir string) { c.MaxBytesTotal = -1 }, "max bytes must be positive", true},
+		{"max file exceeds total", func(dir string) { c.MaxBytesPerFile = 2000 }, "max bytes per file (2000) exceeds max bytes total (1000)", true},
+		{"minutes per gigabyte not positive", func(dir string) { c.MinutesPerGigabyte = 0 }, "minutes per gigabyte must be positive", true},
+		{"data directory is a file", func(dir string) {
+			c.ContentDirectory = filepath.Join(dir, "file")
+			ioutil.WriteFile(c.ContentDirectory, []byte("test"), 0644)
+		}, "is not a directory", true},
+		{"missing parent directory", func(dir string) {
+			c.ContentDirectory = filepath.Join(dir, "a", "b")
+		}, "cannot be created", false},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		c.Port = "8222"
+		c.PublicURL = "http://localhost:8222"
+		c.MaxBytesPerFile = 100
+		c.MaxBytesTotal = 1000
+		c.MinutesPerGigabyte = 30
+		c.ContentDirectory = dir
+		tt.setup(dir)
+
+		err := checkConfig()
+		if tt.err == "" {
+			assert.Nil(t, err, tt.name)
+		} else if assert.NotNil(t, err, tt.name) {
+			assert.Contains(t, err.Error(), tt.err, tt.name)
+		}
+
+		// --check must not leave anything behind
+		_, errStat := os.Stat(c.ContentDirectory)
+		assert.Equal(t, tt.exists, errStat == nil, tt.name)
+		files, _ := ioutil.ReadDir(dir)
+		for _, f := range files {
+			assert.False(t, strings.HasPrefix(f.Name(), "sharetemp"), tt.name)
+		}
+		_, errStat = os.Stat(filepath.Join(dir, "a"))
+		assert.True(t, os.IsNotExist(errStat), tt.name)
+	}
+}
+
+func TestHandleAPIConfig(t *testing.T) {
+	oldConfig := c
+	defer func() { c = oldConfig }()
+	c.MaxBytesPerFile = 100000000
+	c.MaxBytesPerFileHuman = HumanizeBytes(c.MaxBytesPerFile)
+	c.MaxBytesTotal = 10000000000
+	c.MinutesPerGigabyte = 30
+
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest("GET", "/api/config", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var config map[string]interface{}
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &config))
+	assert.Equal(t, map[string]interface{}{
+		"max_bytes_per_file":       float64(100000000),
+		"max_bytes_per_file_human": "100 MB",
+		"max_bytes_total":          float64(10000000000),
+		"minutes_per_gigabyte":     float64(30),
+	}, config)
+}